@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/TalkTakesTime/brainfuck"
+	"io/ioutil"
+	"os"
+)
+
+var (
+	pkg      = flag.String("pkg", "main", "package name for the generated Go file")
+	funcName = flag.String("func", "Run", "name of the generated function")
+	out      = flag.String("out", "", "output file (defaults to stdout)")
+)
+
+func main() {
+	flag.Parse()
+	args := flag.Args()
+	if len(args) != 1 {
+		fmt.Println("usage: bfc [-pkg name] [-func name] [-out file] <program.bf>")
+		return
+	}
+
+	filename := args[0]
+	program, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("File %s could not be used\n", filename)
+		return
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Printf("File %s could not be created\n", *out)
+			return
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := brainfuck.Transpile(string(program), *pkg, *funcName, w); err != nil {
+		fmt.Printf("File %s does not contain a valid Brainfuck program: %s\n", filename, err)
+	}
+}
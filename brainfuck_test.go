@@ -1,6 +1,10 @@
 package brainfuck
 
-import "testing"
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
 
 func TestValidate(t *testing.T) {
 	code := "["
@@ -33,3 +37,24 @@ func TestValidate(t *testing.T) {
 		t.Errorf("\"%s\" is invalid code but marked as valid\n", code)
 	}
 }
+
+func TestInterpreterIsolation(t *testing.T) {
+	var outA, outB bytes.Buffer
+	a := NewInterpreter(WithOutput(&outA), WithInput(strings.NewReader("")))
+	b := NewInterpreter(WithOutput(&outB), WithInput(strings.NewReader("")))
+
+	// "A" on a, "B" on b -- running one must not disturb the other's tape
+	if err := a.Run(strings.Repeat("+", 65)+".", true); err != nil {
+		t.Fatalf("unexpected error running program on a: %s", err.Error())
+	}
+	if err := b.Run(strings.Repeat("+", 66)+".", true); err != nil {
+		t.Fatalf("unexpected error running program on b: %s", err.Error())
+	}
+
+	if got := outA.String(); got != "A\n" {
+		t.Errorf("expected a to output \"A\\n\", got %q", got)
+	}
+	if got := outB.String(); got != "B\n" {
+		t.Errorf("expected b to output \"B\\n\", got %q", got)
+	}
+}
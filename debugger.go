@@ -0,0 +1,267 @@
+package brainfuck
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// Debugger drives a compiled Program through an Interpreter one op at a
+// time, pausing for an interactive REPL on breakpoints and watchpoints. It
+// reads commands from and writes prompts/output to the same io.Reader and
+// io.Writer the Interpreter was configured with, so a program's own "," and
+// "." share a channel with the debugger -- a terminal typing commands at a
+// pause and feeding the program input once it resumes, or a test driving
+// both from the same bytes.Buffer/strings.Reader.
+//
+// The "!! break" special instruction pauses unconditionally wherever it
+// appears in the source; breakpoints can also be set and cleared on the fly
+// from the REPL by source byte offset, using each Op's SrcPos.
+type Debugger struct {
+	interp      *Interpreter
+	breakpoints map[int]bool
+	watches     map[int]uint32
+	trace       bool
+}
+
+// NewDebugger creates a Debugger that steps the given Interpreter through a
+// Program, instead of running it straight through with Interpreter.Run.
+func NewDebugger(interp *Interpreter) *Debugger {
+	return &Debugger{
+		interp:      interp,
+		breakpoints: make(map[int]bool),
+		watches:     make(map[int]uint32),
+	}
+}
+
+// Run compiles and runs code on the Debugger's Interpreter, pausing for the
+// REPL whenever a breakpoint or watchpoint is hit. It returns an error if
+// the program is invalid or if the Interpreter encounters a runtime error.
+func (d *Debugger) Run(code string) error {
+	prog, err := Compile(code)
+	if err != nil {
+		return err
+	}
+
+	d.interp.err = nil
+
+	// The REPL reads commands from the same stream the program's own ","
+	// reads from, so both have to share one *bufio.Reader: if the REPL
+	// buffered d.interp.in separately (e.g. via bufio.NewScanner), its
+	// read-ahead would swallow bytes the program's Input() was meant to
+	// see. Wrapping interp.in in place -- once -- means Input's
+	// fmt.Fscanf(interp.in, ...) reads from the very same buffer.
+	in, ok := d.interp.in.(*bufio.Reader)
+	if !ok {
+		in = bufio.NewReader(d.interp.in)
+		d.interp.in = in
+	}
+
+	pc := 0
+	for pc < len(prog.Ops) {
+		op := prog.Ops[pc]
+		if d.trace {
+			fmt.Fprintf(d.interp.out, "trace: pc=%d src=%d op=%v\n", pc, op.SrcPos, op.Code)
+		}
+		if d.atBreakpoint(op) {
+			if !d.repl(in, prog, &pc) {
+				break
+			}
+			if d.interp.err != nil {
+				return d.interp.err
+			}
+			continue
+		}
+
+		pc = d.interp.step(prog, pc)
+		if d.interp.err != nil {
+			return d.interp.err
+		}
+		if d.checkWatches() {
+			if !d.repl(in, prog, &pc) {
+				break
+			}
+		}
+	}
+
+	fmt.Fprintln(d.interp.out)
+	return d.interp.err
+}
+
+// atBreakpoint reports whether op should pause the Debugger: either it's
+// the "!! break" special instruction, or its source position matches a
+// breakpoint set via the REPL's "break" command.
+func (d *Debugger) atBreakpoint(op Op) bool {
+	if op.Code == OpSpecial && len(op.Special) > 0 && op.Special[0] == "break" {
+		return true
+	}
+	return d.breakpoints[op.SrcPos]
+}
+
+// checkWatches reports whether any watched cell's value has changed since
+// it was last checked, printing a message for each one that has.
+func (d *Debugger) checkWatches() bool {
+	hit := false
+	for cell, last := range d.watches {
+		idx, ok := d.safeIndex(cell)
+		if !ok {
+			continue
+		}
+		if cur := d.interp.tape[idx]; cur != last {
+			fmt.Fprintf(d.interp.out, "watch: cell %d changed from %d to %d\n", cell, last, cur)
+			d.watches[cell] = cur
+			hit = true
+		}
+	}
+	return hit
+}
+
+// safeIndex translates a logical cell index into a physical tape index
+// without touching interp.err, so that inspecting an out-of-range cell from
+// the REPL can never itself abort a TapeFixedError program.
+func (d *Debugger) safeIndex(cell int) (int, bool) {
+	if d.interp.tapeMode == TapeGrowRight {
+		return d.interp.growTo(cell), true
+	}
+	if cell < 0 || cell >= len(d.interp.tape) {
+		return 0, false
+	}
+	return cell, true
+}
+
+// repl prompts for and runs debugger commands until the program should
+// resume ("continue" or "step"), returning false if the input stream was
+// exhausted and the run should stop entirely. in is the same *bufio.Reader
+// backing the Interpreter's "," so the two never race over the stream.
+func (d *Debugger) repl(in *bufio.Reader, prog *Program, pc *int) bool {
+	for {
+		fmt.Fprint(d.interp.out, "(bfdbg) ")
+		line, rerr := in.ReadString('\n')
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			if rerr != nil {
+				return false
+			}
+			continue
+		}
+
+		switch fields[0] {
+		case "step":
+			n := 1
+			if len(fields) > 1 {
+				if v, err := strconv.Atoi(fields[1]); err == nil {
+					n = v
+				}
+			}
+			for i := 0; i < n && *pc < len(prog.Ops) && d.interp.err == nil; i++ {
+				*pc = d.interp.step(prog, *pc)
+				d.checkWatches()
+			}
+			return true
+		case "continue":
+			// run the op that triggered the pause before resuming freely,
+			// so a breakpoint doesn't just re-trigger on the next iteration
+			*pc = d.interp.step(prog, *pc)
+			d.checkWatches()
+			return true
+		case "break":
+			if off, ok := intArg(fields, 1); ok {
+				d.breakpoints[off] = true
+			}
+		case "unbreak":
+			if off, ok := intArg(fields, 1); ok {
+				delete(d.breakpoints, off)
+			}
+		case "watch":
+			if cell, ok := intArg(fields, 1); ok {
+				if idx, ok := d.safeIndex(cell); ok {
+					d.watches[cell] = d.interp.tape[idx]
+				}
+			}
+		case "print":
+			start, end := d.interp.pointer-5, d.interp.pointer+5
+			if s, ok := intArg(fields, 1); ok {
+				if e, ok := intArg(fields, 2); ok {
+					start, end = s, e
+				}
+			}
+			start, end, ok := clampPrintRange(start, end)
+			if !ok {
+				fmt.Fprintf(d.interp.out, "print: range too wide (max %d cells)\n", maxPrintCells)
+				break
+			}
+			fmt.Fprintln(d.interp.out, d.interp.FormatCells(start, end))
+		case "set":
+			cell, ok1 := intArg(fields, 1)
+			val, ok2 := intArg(fields, 2)
+			if ok1 && ok2 {
+				if idx, ok := d.safeIndex(cell); ok {
+					d.interp.tape[idx] = uint32(val) & d.interp.cellMask
+				}
+			}
+		case "goto":
+			if cell, ok := intArg(fields, 1); ok {
+				d.interp.pointer = cell
+			}
+		case "trace":
+			if len(fields) > 1 {
+				d.trace = fields[1] == "on"
+			}
+		case "dump":
+			if len(fields) > 1 {
+				d.dump(fields[1])
+			}
+		default:
+			fmt.Fprintf(d.interp.out, "unknown command: %s\n", fields[0])
+		}
+
+		if rerr != nil {
+			return false
+		}
+	}
+}
+
+// maxPrintCells bounds how many cells a single "print" command will
+// format, so a mistyped or adversarial range (e.g. "print 0 100000")
+// can't wedge the debugger in an enormous dump.
+const maxPrintCells = 2000
+
+// clampPrintRange normalizes a user-typed [start, end] range for "print",
+// swapping the bounds if reversed and rejecting a range wider than
+// maxPrintCells outright, rather than trying to format it.
+func clampPrintRange(start, end int) (int, int, bool) {
+	if end < start {
+		start, end = end, start
+	}
+	if end-start+1 > maxPrintCells {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// dump writes a snapshot of every currently allocated cell to path, in the
+// same format FormatCells uses.
+func (d *Debugger) dump(path string) {
+	start, end := 0, len(d.interp.tape)-1
+	if d.interp.tapeMode == TapeGrowRight {
+		start, end = -d.interp.origin, len(d.interp.tape)-1-d.interp.origin
+	}
+
+	data := []byte(d.interp.FormatCells(start, end) + "\n")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		fmt.Fprintf(d.interp.out, "dump failed: %s\n", err.Error())
+	}
+}
+
+// intArg parses fields[i] as an int, returning ok=false if there's no such
+// field or it isn't a valid integer.
+func intArg(fields []string, i int) (int, bool) {
+	if i >= len(fields) {
+		return 0, false
+	}
+	v, err := strconv.Atoi(fields[i])
+	return v, err == nil
+}
@@ -0,0 +1,94 @@
+package brainfuck
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDebuggerBreakAndContinue(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("print\ncontinue\n")
+	interp := NewInterpreter(WithOutput(&out), WithInput(in))
+	dbg := NewDebugger(interp)
+
+	if err := dbg.Run("+++!! break+."); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got := out.String()
+	if !strings.Contains(got, "(bfdbg)") {
+		t.Errorf("expected a debugger prompt in output, got %q", got)
+	}
+	// 3 increments before the break, 1 more after continuing
+	if !bytes.Contains(out.Bytes(), []byte{4}) {
+		t.Errorf("expected output to contain cell value 4, got %v", out.Bytes())
+	}
+}
+
+func TestDebuggerBreakpointAndSet(t *testing.T) {
+	var out bytes.Buffer
+	// src offset 3 is the "." -- set the cell to 9 before it runs
+	in := strings.NewReader("set 0 9\ncontinue\n")
+	interp := NewInterpreter(WithOutput(&out), WithInput(in))
+	dbg := NewDebugger(interp)
+	dbg.breakpoints[3] = true
+
+	if err := dbg.Run("+++."); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if !bytes.Contains(out.Bytes(), []byte{9}) {
+		t.Errorf("expected output to contain cell value 9, got %v", out.Bytes())
+	}
+}
+
+func TestDebuggerWatch(t *testing.T) {
+	var out bytes.Buffer
+	in := strings.NewReader("watch 0\nstep 3\n")
+	interp := NewInterpreter(WithOutput(&out), WithInput(in))
+	dbg := NewDebugger(interp)
+
+	if err := dbg.Run("!! break+++."); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), "watch: cell 0 changed") {
+		t.Errorf("expected a watch notification, got %q", out.String())
+	}
+}
+
+func TestDebuggerPrintClampsRange(t *testing.T) {
+	var out bytes.Buffer
+	// both of these ranges used to either panic (a start far below 0) or
+	// hang forever (an end far past the tape length) inside FormatCells
+	in := strings.NewReader("print 0 100000\nprint -40000 0\ncontinue\n")
+	interp := NewInterpreter(WithOutput(&out), WithInput(in))
+	dbg := NewDebugger(interp)
+
+	if err := dbg.Run("!! break."); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if !strings.Contains(out.String(), "range too wide") {
+		t.Errorf("expected an out-of-range message, got %q", out.String())
+	}
+}
+
+func TestDebuggerSharesInputWithProgram(t *testing.T) {
+	var out bytes.Buffer
+	// "continue" is the debugger command; "A" is the program's own ","
+	// input -- both have to come off the same stream without either
+	// swallowing the other's bytes
+	in := strings.NewReader("continue\nA")
+	interp := NewInterpreter(WithOutput(&out), WithInput(in))
+	dbg := NewDebugger(interp)
+
+	if err := dbg.Run("!! break,."); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	if !bytes.Contains(out.Bytes(), []byte{'A'}) {
+		t.Errorf("expected the program's \",\" to read %q, got %v", "A", out.Bytes())
+	}
+}
@@ -0,0 +1,468 @@
+package brainfuck
+
+import (
+	"errors"
+	"fmt"
+	"github.com/TalkTakesTime/stack"
+	"strconv"
+)
+
+// Interpreter holds all of the runtime state needed to execute a Brainfuck
+// program: the tape, the pointer, and the loop stack used by OpenLoop and
+// CloseLoop to match "[" and "]". Creating an Interpreter per program
+// (rather than relying on package-level state) allows multiple programs to
+// be run concurrently or repeatedly without interfering with each other.
+type Interpreter struct {
+	tape      []uint32
+	cellMask  uint32
+	pointer   int
+	origin    int // only meaningful under TapeGrowRight; see growTo
+	loopStack stack.Stack
+	err       error
+
+	config
+}
+
+// NewInterpreter creates an Interpreter configured by the given Options. With
+// no Options, it behaves like the original package-level interpreter: a
+// 30000-cell, 8-bit tape that wraps on over/underflow, reading from stdin
+// and writing to stdout.
+func NewInterpreter(opts ...Option) *Interpreter {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &Interpreter{
+		tape:     make([]uint32, cfg.tapeLength),
+		cellMask: cfg.cellSize.mask(),
+		config:   cfg,
+	}
+}
+
+// cell returns the value of the cell under the pointer.
+func (interp *Interpreter) cell() uint32 {
+	return interp.tape[interp.phys(interp.pointer)]
+}
+
+// setCell sets the cell under the pointer to v, wrapped to the
+// interpreter's CellSize.
+func (interp *Interpreter) setCell(v uint32) {
+	interp.tape[interp.phys(interp.pointer)] = v & interp.cellMask
+}
+
+// addToCell adds delta to the cell at tape index idx, wrapped to the
+// interpreter's CellSize. delta may represent a negative value via two's
+// complement (e.g. uint32(int32(-1))); wrapping commutes with addition and
+// multiplication modulo 2^32, so this is equivalent to signed arithmetic
+// for every CellSize the package supports.
+func (interp *Interpreter) addToCell(idx int, delta uint32) {
+	interp.tape[idx] = (interp.tape[idx] + delta) & interp.cellMask
+}
+
+// MoveLeft represents the Brainfuck instruction "<". It moves the pointer
+// left by one cell; past the start of the tape it wraps to the end, records
+// an error, or grows the tape, according to the interpreter's TapeMode
+func (interp *Interpreter) MoveLeft() {
+	interp.movePointer(-1)
+}
+
+// MoveRight represents the Brainfuck instruction ">". It moves the pointer
+// right by one cell; past the end of the tape it wraps to 0, records an
+// error, or grows the tape, according to the interpreter's TapeMode
+func (interp *Interpreter) MoveRight() {
+	interp.movePointer(1)
+}
+
+// Increment represents the Brainfuck instruction "+". It increments the
+// memory cell under the pointer
+func (interp *Interpreter) Increment() {
+	interp.setCell(interp.cell() + 1)
+}
+
+// Decrement represents the Brainfuck instruction "-". It decrements the
+// memory cell under the pointer
+func (interp *Interpreter) Decrement() {
+	interp.setCell(interp.cell() - 1)
+}
+
+// Output represents the Brainfuck instruction ".". It writes the character
+// value of the cell under the pointer to the interpreter's output
+func (interp *Interpreter) Output() {
+	fmt.Fprintf(interp.out, "%c", rune(interp.cell()))
+}
+
+// Input represents the Brainfuck instruction ",". It reads a character from
+// the interpreter's input and stores it in the cell under the pointer. Once
+// input is exhausted, what happens is governed by the interpreter's
+// EOFPolicy.
+func (interp *Interpreter) Input() {
+	var r rune
+	if _, err := fmt.Fscanf(interp.in, "%c", &r); err != nil {
+		switch interp.eofPolicy {
+		case EOFZero:
+			interp.setCell(0)
+		case EOFMinusOne:
+			interp.setCell(interp.cellMask)
+		case EOFLeaveUnchanged:
+			// leave the cell as-is
+		case EOFError:
+			interp.err = fmt.Errorf("EOF reached reading input at cell %d", interp.pointer)
+		}
+		return
+	}
+	interp.setCell(uint32(r))
+}
+
+// OpenLoop represents the Brainfuck instruction "[". It forms the opening
+// part of a loop. If the cell under the pointer is 0, returns true to
+// indicate to skip to the next ]
+func (interp *Interpreter) OpenLoop(pos int) bool {
+	if interp.cell() == 0 {
+		return true
+	}
+	interp.loopStack.Push(pos)
+	return false
+}
+
+// CloseLoop represents the Brainfuck instruction "]".
+// It closes a loop and returns the index of the matching open brace in the
+// code, if the cell under the pointer is not 0. Otherwise returns -1
+func (interp *Interpreter) CloseLoop() int {
+	p, err := interp.loopStack.Pop()
+	if err != nil {
+		panic(err)
+	}
+
+	if interp.cell() == 0 {
+		return -1
+	}
+	return p.(int)
+}
+
+// RunSpecialInstruction executes a non-standard runtime instruction for
+// various utilities not included in a standard Brainfuck interpreter, such as
+// "!! clear", which clears the tape so that multiple Brainfuck programs
+// can be run from a single file.
+//
+// All special instructions are of the form "!! instruction", and the valid
+// instructions are as follows:
+//   - clear: clears the tape and resets the pointer to position 0
+//   - print: prints the contents of the 11 cells surrounding the pointer
+//   - printn: prints the contents of the cells from n/2 left to n/2 right
+//     of the pointer (n+1 cells when n is even)
+func (interp *Interpreter) RunSpecialInstruction(inst []string) {
+	switch inst[0] {
+	case "clear":
+		interp.ClearTape()
+	case "print":
+		fmt.Fprintln(interp.out, interp.FormatCells(interp.pointer-5, interp.pointer+5))
+	case "printn":
+		n, err := strconv.Atoi(inst[1])
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(interp.out, interp.FormatCells(interp.pointer-n/2, interp.pointer+n/2))
+	}
+}
+
+// FormatCells formats the cells from indices start to end (inclusive)
+// into an easily human-readable format and returns the result as a string.
+// Under TapeFixedWrap, out-of-range indices wrap the way the pointer does;
+// under TapeFixedError they're clamped to the tape's bounds; under
+// TapeGrowRight the tape is grown to cover the requested range.
+func (interp *Interpreter) FormatCells(start, end int) string {
+	if interp.tapeMode == TapeGrowRight {
+		return interp.formatCellsGrow(start, end)
+	}
+	return interp.formatCellsFixed(start, end)
+}
+
+func (interp *Interpreter) formatCellsFixed(start, end int) string {
+	tapeLength := len(interp.tape)
+	if end < start {
+		end = start
+	}
+
+	// Bound the number of cells printed to at most the whole tape, so a
+	// wildly out-of-range request (e.g. a user-typed "print 0 100000")
+	// can't loop forever, and reduce start to a valid index up front, so
+	// it can't panic indexing interp.tape below -- rather than adjusting
+	// start/end once and trusting the loop to land exactly on end+1.
+	count := end - start + 1
+	if count > tapeLength {
+		count = tapeLength
+	}
+	if interp.tapeMode == TapeFixedWrap {
+		start %= tapeLength
+		if start < 0 {
+			start += tapeLength
+		}
+	} else {
+		if start < 0 {
+			start = 0
+		} else if start >= tapeLength {
+			start = tapeLength - 1
+		}
+		if end >= tapeLength {
+			end = tapeLength - 1
+		}
+		if end < start {
+			end = start
+		}
+		count = end - start + 1
+	}
+
+	indicesText := ""
+	cellsText := "[\t"
+	i := start
+	for n := 0; n < count; n++ {
+		indicesText += fmt.Sprintf("\t%d", i)
+		cellsText += fmt.Sprintf("%d\t", interp.tape[i])
+
+		i++
+		if interp.tapeMode == TapeFixedWrap {
+			if i >= tapeLength {
+				i = 0
+			}
+		} else if i >= tapeLength {
+			i = tapeLength - 1
+		}
+	}
+	return indicesText + "\n" + cellsText + "]"
+}
+
+func (interp *Interpreter) formatCellsGrow(start, end int) string {
+	if end < start {
+		end = start
+	}
+
+	startIdx := interp.growTo(start)
+	interp.growTo(end)
+	origin := startIdx - start
+
+	indicesText := ""
+	cellsText := "[\t"
+	for i := start; i <= end; i++ {
+		indicesText += fmt.Sprintf("\t%d", i)
+		cellsText += fmt.Sprintf("%d\t", interp.tape[i+origin])
+	}
+	return indicesText + "\n" + cellsText + "]"
+}
+
+// Validate tests to see if the given string of code contains any syntax
+// errors -- namely, unmatched closing or opening braces.
+func (interp *Interpreter) Validate(code string) error {
+	var testStack stack.Stack
+	for i, r := range code {
+		c := string(r)
+		if c == "[" {
+			testStack.Push(i)
+		} else if c == "]" {
+			_, err := testStack.Pop()
+			if err != nil {
+				return errors.New("Syntax error: closing brace without " +
+					"matched opening brace")
+			}
+		}
+	}
+	if testStack.Length() != 0 {
+		return errors.New("Syntax error: opening brace without matching " +
+			"closing brace")
+	}
+
+	return nil
+}
+
+// Run compiles and runs the given Brainfuck program on this Interpreter,
+// clearing the tape before running if clearTape is true. Returns an error if
+// the program is invalid, otherwise returns nil.
+func (interp *Interpreter) Run(code string, clearTape bool) error {
+	prog, err := Compile(code)
+	if err != nil {
+		return err
+	}
+
+	if clearTape {
+		interp.ClearTape()
+	}
+
+	interp.err = nil
+	interp.exec(prog)
+	if interp.err != nil {
+		return interp.err
+	}
+
+	// add a new line to ensure nice ending
+	fmt.Fprintln(interp.out)
+
+	return nil
+}
+
+// exec runs a compiled Program against the interpreter's tape, replacing
+// the old approach of re-scanning the source on every skipped loop with a
+// tight switch over precomputed jump targets.
+func (interp *Interpreter) exec(prog *Program) {
+	pc := 0
+	for pc < len(prog.Ops) && interp.err == nil {
+		pc = interp.step(prog, pc)
+	}
+}
+
+// step executes the single op at prog.Ops[pc] and returns the index of the
+// op that should run next. It is the shared core of exec and of Debugger's
+// run loop, which needs to pause between ops rather than run a Program
+// straight through.
+func (interp *Interpreter) step(prog *Program, pc int) int {
+	op := prog.Ops[pc]
+	switch op.Code {
+	case OpAdd:
+		interp.setCell(interp.cell() + uint32(int32(op.Operand)))
+	case OpMove:
+		interp.movePointer(op.Operand)
+	case OpOut:
+		for k := 0; k < op.Operand; k++ {
+			interp.Output()
+		}
+	case OpIn:
+		for k := 0; k < op.Operand && interp.err == nil; k++ {
+			interp.Input()
+		}
+	case OpJmpZ:
+		if interp.cell() == 0 {
+			return op.Operand
+		}
+	case OpJmpNZ:
+		if interp.cell() != 0 {
+			return op.Operand
+		}
+	case OpClear:
+		interp.setCell(0)
+	case OpScan:
+		for interp.cell() != 0 {
+			interp.movePointer(op.Operand)
+			if interp.err != nil {
+				break
+			}
+		}
+	case OpMulMove:
+		counter := interp.cell()
+		if counter != 0 {
+			for _, t := range op.Targets {
+				idx := interp.resolveOffset(t.Offset)
+				if interp.err != nil {
+					return pc + 1
+				}
+				interp.addToCell(idx, counter*uint32(int32(t.Mul)))
+			}
+		}
+		interp.setCell(0)
+	case OpSpecial:
+		interp.RunSpecialInstruction(op.Special)
+	}
+	return pc + 1
+}
+
+// movePointer advances the pointer by delta cells (delta may be negative),
+// according to the interpreter's TapeMode: wrapping around the tape,
+// recording a range error, or growing the tape.
+func (interp *Interpreter) movePointer(delta int) {
+	switch interp.tapeMode {
+	case TapeGrowRight:
+		// pointer is a logical coordinate under this mode; growth (and the
+		// physical index it maps to) is resolved lazily on access
+		interp.pointer += delta
+	case TapeFixedError:
+		n := len(interp.tape)
+		next := interp.pointer + delta
+		if next < 0 || next >= n {
+			interp.err = fmt.Errorf("pointer out of range: %d (tape has %d cells)", next, n)
+			return
+		}
+		interp.pointer = next
+	default: // TapeFixedWrap
+		n := len(interp.tape)
+		next := (interp.pointer + delta) % n
+		if next < 0 {
+			next += n
+		}
+		interp.pointer = next
+	}
+}
+
+// resolveOffset returns the physical tape index of the cell offset cells
+// from the pointer, without moving the pointer, applying the same
+// wrapping/error/growth rules as movePointer.
+func (interp *Interpreter) resolveOffset(offset int) int {
+	return interp.phys(interp.pointer + offset)
+}
+
+// phys translates a logical tape position into a physical slice index
+// according to the interpreter's TapeMode. Under TapeFixedWrap and
+// TapeFixedError, pointer is always already a valid physical index, so
+// this mostly re-validates it; under TapeGrowRight it grows the tape as
+// needed. If TapeFixedError finds logical out of range, it records the
+// error on interp.err and returns 0, which callers must not use -- exec
+// checks interp.err after every op and stops.
+func (interp *Interpreter) phys(logical int) int {
+	switch interp.tapeMode {
+	case TapeGrowRight:
+		return interp.growTo(logical)
+	case TapeFixedError:
+		n := len(interp.tape)
+		if logical < 0 || logical >= n {
+			interp.err = fmt.Errorf("pointer out of range: %d (tape has %d cells)", logical, n)
+			return 0
+		}
+		return logical
+	default: // TapeFixedWrap
+		n := len(interp.tape)
+		logical %= n
+		if logical < 0 {
+			logical += n
+		}
+		return logical
+	}
+}
+
+// growTo grows the tape as needed so that logical is addressable, doubling
+// whichever side needs room so repeated moves in one direction don't force
+// a reallocation on every single step, and returns logical's physical
+// slice index. Growing left shifts every existing cell's physical index,
+// which is why the pointer is tracked as a logical coordinate rather than
+// a slice index everywhere else in the interpreter.
+func (interp *Interpreter) growTo(logical int) int {
+	phys := logical + interp.origin
+	if phys >= 0 && phys < len(interp.tape) {
+		return phys
+	}
+
+	growLeft, growRight := 0, 0
+	if phys < 0 {
+		growLeft = -phys * 2
+		if growLeft < len(interp.tape) {
+			growLeft = len(interp.tape)
+		}
+	}
+	if over := phys - len(interp.tape) + 1; over > 0 {
+		growRight = over * 2
+		if growRight < len(interp.tape) {
+			growRight = len(interp.tape)
+		}
+	}
+
+	newTape := make([]uint32, growLeft+len(interp.tape)+growRight)
+	copy(newTape[growLeft:], interp.tape)
+	interp.tape = newTape
+	interp.origin += growLeft
+
+	return logical + interp.origin
+}
+
+// ClearTape resets the tape to a fresh, zeroed TapeLength cells and resets
+// the pointer position to 0.
+func (interp *Interpreter) ClearTape() {
+	interp.tape = make([]uint32, interp.tapeLength)
+	interp.origin = 0
+	interp.pointer = 0
+}
@@ -0,0 +1,233 @@
+package brainfuck
+
+import "sort"
+
+// OpCode identifies the kind of instruction a compiled Op represents.
+type OpCode int
+
+const (
+	// OpAdd adds Operand (which may be negative) to the current cell.
+	OpAdd OpCode = iota
+	// OpMove adds Operand (which may be negative) to the pointer.
+	OpMove
+	// OpOut outputs the current cell Operand times.
+	OpOut
+	// OpIn reads into the current cell Operand times.
+	OpIn
+	// OpJmpZ jumps to Operand if the current cell is 0, otherwise falls
+	// through. Operand is the index of the op after the matching OpJmpNZ.
+	OpJmpZ
+	// OpJmpNZ jumps to Operand if the current cell is nonzero, otherwise
+	// falls through. Operand is the index of the op after the matching
+	// OpJmpZ.
+	OpJmpNZ
+	// OpClear sets the current cell to 0, replacing a "[-]" or "[+]" loop.
+	OpClear
+	// OpScan moves the pointer by Operand (+1 or -1) repeatedly until the
+	// cell under it is 0, replacing a "[>]" or "[<]" loop.
+	OpScan
+	// OpMulMove replaces a copy/multiply loop such as "[->+++>++<<]": it
+	// adds the current cell's value times each Target's Mul to the cell at
+	// Target's Offset, then zeroes the current cell.
+	OpMulMove
+	// OpSpecial runs a "!! instruction" special instruction.
+	OpSpecial
+)
+
+// MulTarget describes one destination of an OpMulMove: the cell at Offset
+// (relative to the pointer position when the op runs) has counter*Mul
+// added to it, where counter is the current cell's value before it is
+// zeroed.
+type MulTarget struct {
+	Offset int
+	Mul    int
+}
+
+// Op is a single instruction in a compiled Program. SrcPos is the byte
+// offset in the original source the op was compiled from, and is kept so
+// that tools built on top of the IR (such as a debugger) can still report
+// positions in terms of the source a user wrote.
+type Op struct {
+	Code    OpCode
+	Operand int
+	Targets []MulTarget
+	Special []string
+	SrcPos  int
+}
+
+// Program is the compiled form of a Brainfuck source string, ready to be
+// executed by an Interpreter's exec method.
+type Program struct {
+	Ops []Op
+}
+
+// Compile translates a Brainfuck source string into a Program. It collapses
+// runs of "+"/"-", ">"/"<", "."/"," into single ops, precomputes the jump
+// targets for "["/"]" pairs, and recognizes a handful of common loop shapes
+// ("[-]"/"[+]", "[>]"/"[<]", and copy/multiply loops) so the executor never
+// has to rescan the source to find a matching brace.
+func Compile(code string) (*Program, error) {
+	if err := Validate(code); err != nil {
+		return nil, err
+	}
+
+	var ops []Op
+	var openStack []int
+
+	n := len(code)
+	for i := 0; i < n; {
+		switch code[i] {
+		case '+', '-':
+			start, delta := i, 0
+			for i < n && (code[i] == '+' || code[i] == '-') {
+				if code[i] == '+' {
+					delta++
+				} else {
+					delta--
+				}
+				i++
+			}
+			ops = append(ops, Op{Code: OpAdd, Operand: delta, SrcPos: start})
+		case '>', '<':
+			start, delta := i, 0
+			for i < n && (code[i] == '>' || code[i] == '<') {
+				if code[i] == '>' {
+					delta++
+				} else {
+					delta--
+				}
+				i++
+			}
+			ops = append(ops, Op{Code: OpMove, Operand: delta, SrcPos: start})
+		case '.':
+			start, count := i, 0
+			for i < n && code[i] == '.' {
+				count++
+				i++
+			}
+			ops = append(ops, Op{Code: OpOut, Operand: count, SrcPos: start})
+		case ',':
+			start, count := i, 0
+			for i < n && code[i] == ',' {
+				count++
+				i++
+			}
+			ops = append(ops, Op{Code: OpIn, Operand: count, SrcPos: start})
+		case '[':
+			if op, end, ok := compileLoopShorthand(code, i); ok {
+				op.SrcPos = i
+				ops = append(ops, op)
+				i = end + 1
+				continue
+			}
+			openStack = append(openStack, len(ops))
+			ops = append(ops, Op{Code: OpJmpZ, SrcPos: i})
+			i++
+		case ']':
+			openIdx := openStack[len(openStack)-1]
+			openStack = openStack[:len(openStack)-1]
+			ops = append(ops, Op{Code: OpJmpNZ, Operand: openIdx + 1, SrcPos: i})
+			ops[openIdx].Operand = len(ops)
+			i++
+		case '!':
+			match := SpecialInstructionRegex.FindStringSubmatch(code[i:])
+			if len(match) > 0 {
+				ops = append(ops, Op{Code: OpSpecial, Special: match[1:], SrcPos: i})
+			}
+			i++
+		default:
+			i++
+		}
+	}
+
+	return &Program{Ops: ops}, nil
+}
+
+// compileLoopShorthand checks whether the loop opening at code[start] (a
+// "[") matches one of the well-known optimizable shapes, and if so returns
+// the Op to emit in its place along with the index of the loop's matching
+// "]". ok is false if the loop should be compiled as an ordinary jump pair
+// instead.
+func compileLoopShorthand(code string, start int) (Op, int, bool) {
+	end := matchingBrace(code, start)
+	if end < 0 {
+		return Op{}, 0, false
+	}
+	body := code[start+1 : end]
+
+	switch body {
+	case "-", "+":
+		return Op{Code: OpClear}, end, true
+	case ">":
+		return Op{Code: OpScan, Operand: 1}, end, true
+	case "<":
+		return Op{Code: OpScan, Operand: -1}, end, true
+	}
+
+	if targets, ok := parseMulLoop(body); ok {
+		return Op{Code: OpMulMove, Targets: targets}, end, true
+	}
+
+	return Op{}, 0, false
+}
+
+// matchingBrace returns the index of the "]" matching the "[" at
+// code[start]. Validate has already checked that code is bracket-balanced,
+// so this always finds a match.
+func matchingBrace(code string, start int) int {
+	depth := 0
+	for i := start; i < len(code); i++ {
+		switch code[i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseMulLoop tries to interpret body (a loop's contents, excluding the
+// brackets) as a copy/multiply loop such as "->+>++>+<<<": it must
+// contain only "+-<>", have zero net pointer movement, and decrement its
+// own cell (offset 0) by exactly one. On success it returns the non-zero
+// offsets it writes to and their multipliers.
+func parseMulLoop(body string) ([]MulTarget, bool) {
+	offset := 0
+	deltas := map[int]int{}
+	for _, r := range body {
+		switch r {
+		case '+':
+			deltas[offset]++
+		case '-':
+			deltas[offset]--
+		case '>':
+			offset++
+		case '<':
+			offset--
+		default:
+			return nil, false
+		}
+	}
+	if offset != 0 || deltas[0] != -1 {
+		return nil, false
+	}
+
+	var targets []MulTarget
+	for off, mul := range deltas {
+		if off == 0 || mul == 0 {
+			continue
+		}
+		targets = append(targets, MulTarget{Offset: off, Mul: mul})
+	}
+	if len(targets) == 0 {
+		return nil, false
+	}
+	// map iteration order is randomized; sort so Compile is deterministic
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Offset < targets[j].Offset })
+
+	return targets, true
+}
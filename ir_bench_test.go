@@ -0,0 +1,163 @@
+package brainfuck
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// benchmarkRunFile runs the given testdata program against a fresh
+// Interpreter on every iteration, discarding output.
+func benchmarkRunFile(b *testing.B, path string) {
+	code, err := ioutil.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	src := string(code)
+
+	if _, err := Compile(src); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interp := NewInterpreter(WithOutput(ioutil.Discard), WithInput(strings.NewReader("")))
+		if err := interp.Run(src, false); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// These benchmarks stand in for the classic mandelbrot.bf and hanoi.bf
+// programs: both are copy/multiply- and nested-loop-heavy, which is
+// exactly what OpMulMove and precomputed jump targets optimize, but
+// neither is available in this sandbox (no network access to fetch the
+// canonical sources, and hand-transcribing programs that size from memory
+// risks a silent transcription error producing the wrong, or
+// non-terminating, program). testdata/mul_loop.bf and
+// testdata/nested_loops.bf are synthetic fixtures built to exercise the
+// same optimizations at a scale large enough to show up in a benchmark.
+
+// BenchmarkRunMulLoop exercises the OpMulMove optimization: without it, a
+// copy/multiply loop costs one interpreter step per unit of the counter
+// cell instead of a single op.
+func BenchmarkRunMulLoop(b *testing.B) {
+	benchmarkRunFile(b, "testdata/mul_loop.bf")
+}
+
+// BenchmarkRunNestedLoops exercises the precomputed jump targets: the
+// original interpreter rescans forward to the matching "]" every time an
+// outer loop is (re-)entered, which is quadratic in the number of outer
+// iterations.
+func BenchmarkRunNestedLoops(b *testing.B) {
+	benchmarkRunFile(b, "testdata/nested_loops.bf")
+}
+
+// stepNaive is a benchmark-only stand-in for Interpreter.step that ignores
+// Op.Operand on OpJmpZ/OpJmpNZ and instead scans prog.Ops for the matching
+// brace every time a jump is taken, the way the interpreter worked before
+// Compile started precomputing jump targets. It exists only to measure the
+// speedup precomputed targets give, since mandelbrot.bf and hanoi.bf -- the
+// canonical benchmarks the original request asked for -- aren't reachable
+// in this sandbox (see the note above BenchmarkRunMulLoop).
+func stepNaive(interp *Interpreter, prog *Program, pc int) int {
+	op := prog.Ops[pc]
+	switch op.Code {
+	case OpJmpZ:
+		if interp.cell() == 0 {
+			return scanToMatch(prog.Ops, pc, 1) + 1
+		}
+	case OpJmpNZ:
+		if interp.cell() != 0 {
+			return scanToMatch(prog.Ops, pc, -1) + 1
+		}
+	default:
+		return interp.step(prog, pc)
+	}
+	return pc + 1
+}
+
+// scanToMatch finds the index of the OpJmpZ/OpJmpNZ matching the one at
+// start by walking dir (+1 or -1) through ops and counting nesting depth,
+// the way a rescan over raw "["/"]" source bytes would.
+func scanToMatch(ops []Op, start int, dir int) int {
+	depth := 0
+	for i := start; i >= 0 && i < len(ops); i += dir {
+		switch ops[i].Code {
+		case OpJmpZ:
+			depth += dir
+		case OpJmpNZ:
+			depth -= dir
+		}
+		if i != start && depth == 0 {
+			return i
+		}
+	}
+	return start
+}
+
+// runNaive drives prog to completion using stepNaive instead of
+// Interpreter.step, for the naive/precomputed-jump A/B comparison below.
+func runNaive(interp *Interpreter, prog *Program) {
+	pc := 0
+	for pc < len(prog.Ops) && interp.err == nil {
+		pc = stepNaive(interp, prog, pc)
+	}
+}
+
+// testdata/jump_scan.bf is a single loop whose body never matches one of
+// Compile's recognized shorthands (OpClear/OpScan/OpMulMove), so it stays
+// a genuine OpJmpZ/OpJmpNZ pair with a sizable body between them --
+// nested_loops.bf and mul_loop.bf fold their inner loops into a single
+// OpMulMove, which makes them a poor fixture for isolating jump-target
+// cost specifically, since both the precomputed and naive paths then walk
+// the same handful of ops.
+func loadJumpScanProgram(b *testing.B) *Program {
+	code, err := ioutil.ReadFile("testdata/jump_scan.bf")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	prog, err := Compile(string(code))
+	if err != nil {
+		b.Fatal(err)
+	}
+	return prog
+}
+
+// BenchmarkRunJumpScan exercises the precomputed jump targets on
+// jump_scan.bf's loop: resolving the backward jump on every iteration is a
+// single Op.Operand read. It compiles once up front and times only
+// execution, exactly like BenchmarkRunJumpScanNaive, so the two benchmarks
+// isolate the jump-resolution cost rather than being dominated by Compile.
+func BenchmarkRunJumpScan(b *testing.B) {
+	prog := loadJumpScanProgram(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interp := NewInterpreter(WithOutput(ioutil.Discard), WithInput(strings.NewReader("")))
+		interp.exec(prog)
+		if interp.err != nil {
+			b.Fatal(interp.err)
+		}
+	}
+}
+
+// BenchmarkRunJumpScanNaive runs the same compiled Program as
+// BenchmarkRunJumpScan but through runNaive, so the two benchmarks
+// together measure -- rather than merely assert -- the speedup precomputed
+// jump targets give: runNaive rescans the loop's body for the matching
+// brace on every one of its iterations, while BenchmarkRunJumpScan jumps
+// straight to Op.Operand.
+func BenchmarkRunJumpScanNaive(b *testing.B) {
+	prog := loadJumpScanProgram(b)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		interp := NewInterpreter(WithOutput(ioutil.Discard), WithInput(strings.NewReader("")))
+		runNaive(interp, prog)
+		if interp.err != nil {
+			b.Fatal(interp.err)
+		}
+	}
+}
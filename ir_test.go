@@ -0,0 +1,73 @@
+package brainfuck
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestCompileOptimizations(t *testing.T) {
+	cases := []struct {
+		name string
+		code string
+		want OpCode
+	}{
+		{"clear with minus", "[-]", OpClear},
+		{"clear with plus", "[+]", OpClear},
+		{"scan right", "[>]", OpScan},
+		{"scan left", "[<]", OpScan},
+		{"multiply loop", "[->+++>++<<]", OpMulMove},
+		{"ordinary loop", "[>+<]", OpJmpZ},
+	}
+
+	for _, c := range cases {
+		prog, err := Compile(c.code)
+		if err != nil {
+			t.Fatalf("%s: unexpected compile error: %s", c.name, err.Error())
+		}
+		if len(prog.Ops) == 0 || prog.Ops[0].Code != c.want {
+			t.Errorf("%s: expected first op %v, got %+v", c.name, c.want, prog.Ops)
+		}
+	}
+}
+
+func TestCompileRunLengths(t *testing.T) {
+	prog, err := Compile("+++-->>><.")
+	if err != nil {
+		t.Fatalf("unexpected compile error: %s", err.Error())
+	}
+
+	want := []Op{
+		{Code: OpAdd, Operand: 1},
+		{Code: OpMove, Operand: 2},
+		{Code: OpOut, Operand: 1},
+	}
+	if len(prog.Ops) != len(want) {
+		t.Fatalf("expected %d ops, got %d: %+v", len(want), len(prog.Ops), prog.Ops)
+	}
+	for i, op := range want {
+		if prog.Ops[i].Code != op.Code || prog.Ops[i].Operand != op.Operand {
+			t.Errorf("op %d: expected %+v, got %+v", i, op, prog.Ops[i])
+		}
+	}
+}
+
+func TestRunOptimizedLoops(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter(WithOutput(&out), WithInput(strings.NewReader("")))
+
+	code, err := ioutil.ReadFile("testdata/mul_loop.bf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := interp.Run(string(code), true); err != nil {
+		t.Fatalf("unexpected error running mul_loop.bf: %s", err.Error())
+	}
+
+	got := out.Bytes()
+	want := []byte{30, 20, '\n'}
+	if !bytes.Equal(got, want) {
+		t.Errorf("expected output %v, got %v", want, got)
+	}
+}
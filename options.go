@@ -0,0 +1,142 @@
+package brainfuck
+
+import (
+	"io"
+	"os"
+)
+
+// CellSize selects the width of each cell on the tape. Brainfuck doesn't
+// mandate a cell width, and implementations vary between 8, 16, and 32
+// bits.
+type CellSize int
+
+const (
+	// Cell8 gives each cell 8 bits, wrapping at 255 -- the behaviour of
+	// the original package-global interpreter.
+	Cell8 CellSize = 8
+	// Cell16 gives each cell 16 bits, wrapping at 65535.
+	Cell16 CellSize = 16
+	// Cell32 gives each cell 32 bits, wrapping at 4294967295.
+	Cell32 CellSize = 32
+)
+
+// mask returns the bitmask that keeps a cell value within this CellSize,
+// which doubles as its wraparound point.
+func (s CellSize) mask() uint32 {
+	switch s {
+	case Cell16:
+		return 1<<16 - 1
+	case Cell32:
+		return 1<<32 - 1
+	default:
+		return 1<<8 - 1
+	}
+}
+
+// EOFPolicy controls what "," stores in the current cell once the
+// interpreter's input is exhausted.
+type EOFPolicy int
+
+const (
+	// EOFZero stores 0 in the cell on EOF.
+	EOFZero EOFPolicy = iota
+	// EOFMinusOne stores the cell's maximum value (all bits set) on EOF.
+	EOFMinusOne
+	// EOFLeaveUnchanged leaves the cell's current value untouched on EOF.
+	EOFLeaveUnchanged
+	// EOFError makes Run stop and return an error on EOF.
+	EOFError
+)
+
+// TapeMode controls what happens when the pointer moves past either end of
+// the tape.
+type TapeMode int
+
+const (
+	// TapeFixedWrap keeps the tape a fixed TapeLength cells and wraps the
+	// pointer around to the other end -- the behaviour of the original
+	// package-global interpreter.
+	TapeFixedWrap TapeMode = iota
+	// TapeFixedError keeps the tape a fixed TapeLength cells and makes Run
+	// stop and return an error reporting the offending position instead of
+	// moving out of range.
+	TapeFixedError
+	// TapeGrowRight starts with a TapeLength tape and grows it, doubling
+	// capacity as needed, whenever the pointer moves past either end --
+	// matching the common unbounded-tape convention used by some other
+	// Brainfuck implementations.
+	TapeGrowRight
+)
+
+// config holds the resolved settings for an Interpreter, built up by
+// applying a series of Options to a set of defaults.
+type config struct {
+	tapeLength int
+	tapeMode   TapeMode
+	cellSize   CellSize
+	eofPolicy  EOFPolicy
+	in         io.Reader
+	out        io.Writer
+}
+
+// defaultConfig returns the config used when no Options are given, matching
+// the historical package-level behaviour.
+func defaultConfig() config {
+	return config{
+		tapeLength: TapeLength,
+		tapeMode:   TapeFixedWrap,
+		cellSize:   Cell8,
+		eofPolicy:  EOFZero,
+		in:         os.Stdin,
+		out:        os.Stdout,
+	}
+}
+
+// Option configures an Interpreter. Options are applied in order, so later
+// options override earlier ones.
+type Option func(*config)
+
+// WithTapeLength sets the number of cells on the tape. It defaults to
+// TapeLength.
+func WithTapeLength(n int) Option {
+	return func(c *config) {
+		c.tapeLength = n
+	}
+}
+
+// WithTapeMode controls what happens when the pointer moves past either end
+// of the tape. It defaults to TapeFixedWrap.
+func WithTapeMode(mode TapeMode) Option {
+	return func(c *config) {
+		c.tapeMode = mode
+	}
+}
+
+// WithInput sets the reader that "," reads from. It defaults to os.Stdin.
+func WithInput(r io.Reader) Option {
+	return func(c *config) {
+		c.in = r
+	}
+}
+
+// WithOutput sets the writer that "." writes to. It defaults to os.Stdout.
+func WithOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.out = w
+	}
+}
+
+// WithCellSize sets the width of each tape cell. It defaults to Cell8.
+func WithCellSize(size CellSize) Option {
+	return func(c *config) {
+		c.cellSize = size
+	}
+}
+
+// WithEOFPolicy sets what "," does once input is exhausted. It defaults to
+// EOFZero.
+func WithEOFPolicy(policy EOFPolicy) Option {
+	return func(c *config) {
+		c.eofPolicy = policy
+	}
+}
@@ -0,0 +1,68 @@
+package brainfuck
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestCellSizeWrap(t *testing.T) {
+	cases := []struct {
+		size CellSize
+		want uint32
+	}{
+		{Cell8, 0xFF},
+		{Cell16, 0xFFFF},
+		{Cell32, 0xFFFFFFFF},
+	}
+
+	for _, c := range cases {
+		interp := NewInterpreter(WithCellSize(c.size), WithInput(strings.NewReader("")))
+		// decrementing a zeroed cell should wrap to the CellSize's max value
+		if err := interp.Run("-", true); err != nil {
+			t.Fatalf("cell size %d: unexpected error: %s", c.size, err.Error())
+		}
+
+		want := fmt.Sprintf("%d", c.want)
+		if got := interp.FormatCells(0, 0); !strings.Contains(got, want) {
+			t.Errorf("cell size %d: expected FormatCells to contain %q, got %q", c.size, want, got)
+		}
+	}
+}
+
+func TestEOFPolicy(t *testing.T) {
+	cases := []struct {
+		name   string
+		policy EOFPolicy
+		want   rune
+		err    bool
+	}{
+		{"zero", EOFZero, 0, false},
+		{"minus one", EOFMinusOne, 0xFF, false},
+		{"leave unchanged", EOFLeaveUnchanged, 'x', false},
+		{"error", EOFError, 0, true},
+	}
+
+	for _, c := range cases {
+		var out bytes.Buffer
+		interp := NewInterpreter(WithEOFPolicy(c.policy), WithOutput(&out), WithInput(strings.NewReader("")))
+		// seed the cell with 'x' so EOFLeaveUnchanged has something to
+		// leave in place
+		err := interp.Run(strings.Repeat("+", int('x'))+",.", false)
+
+		if c.err {
+			if err == nil {
+				t.Errorf("%s: expected an error, got none", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", c.name, err.Error())
+		}
+		if got, _ := utf8.DecodeRune(out.Bytes()); got != c.want {
+			t.Errorf("%s: expected %#x, got %#x", c.name, c.want, got)
+		}
+	}
+}
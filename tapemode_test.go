@@ -0,0 +1,84 @@
+package brainfuck
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTapeFixedWrap(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter(
+		WithTapeMode(TapeFixedWrap),
+		WithTapeLength(3),
+		WithOutput(&out),
+		WithInput(strings.NewReader("")),
+	)
+
+	// moving left off the start of a 3-cell tape should wrap to the end
+	if err := interp.Run("<+.", true); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+	want := []byte{1, '\n'}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("expected output %v, got %v", want, got)
+	}
+}
+
+func TestTapeFixedError(t *testing.T) {
+	interp := NewInterpreter(
+		WithTapeMode(TapeFixedError),
+		WithTapeLength(3),
+		WithInput(strings.NewReader("")),
+	)
+
+	if err := interp.Run("<", true); err == nil {
+		t.Error("expected moving left past cell 0 to return an error, got none")
+	}
+}
+
+func TestTapeGrowRight(t *testing.T) {
+	var out bytes.Buffer
+	interp := NewInterpreter(
+		WithTapeMode(TapeGrowRight),
+		WithTapeLength(2),
+		WithOutput(&out),
+		WithInput(strings.NewReader("")),
+	)
+
+	// move well past the initial tape length in both directions; none of
+	// this should wrap or error under TapeGrowRight
+	code := strings.Repeat(">", 10) + "+" + strings.Repeat("<", 20) + "+."
+	if err := interp.Run(code, true); err != nil {
+		t.Fatalf("unexpected error: %s", err.Error())
+	}
+
+	got := out.Bytes()
+	if len(got) == 0 || got[0] != 1 {
+		t.Errorf("expected the final cell to be 1, got %v", got)
+	}
+}
+
+func TestFormatCellsOutOfRangeIsBounded(t *testing.T) {
+	modes := []TapeMode{TapeFixedWrap, TapeFixedError}
+
+	for _, mode := range modes {
+		interp := NewInterpreter(
+			WithTapeMode(mode),
+			WithTapeLength(3),
+			WithInput(strings.NewReader("")),
+		)
+
+		// a start far to the left of a tiny tape must not panic indexing
+		// the underlying slice
+		if got := interp.FormatCells(-40000, 0); got == "" {
+			t.Errorf("mode %v: expected FormatCells(-40000, 0) to return a result", mode)
+		}
+
+		// a range spanning far more than the tape's length must not loop
+		// forever trying to land exactly on end+1
+		if got := interp.FormatCells(0, 100000); got == "" {
+			t.Errorf("mode %v: expected FormatCells(0, 100000) to return a result", mode)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package brainfuck
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// transpileTemplate is the shell of a generated file: a function matching
+// the default Interpreter's behaviour (a fixed 30000-cell, 8-bit tape that
+// wraps on overflow and on out-of-range pointer moves, "," storing 0 once
+// r is exhausted) around the body genBlock produces.
+const transpileTemplate = `// Code generated by brainfuck.Transpile. DO NOT EDIT.
+
+package %s
+
+import (
+	"bufio"
+	"io"
+)
+
+func %s(r io.Reader, w io.Writer) error {
+	tape := make([]byte, 30000)
+	p := 0
+	in := bufio.NewReader(r)
+	mod := func(n int) int {
+		n %%= len(tape)
+		if n < 0 {
+			n += len(tape)
+		}
+		return n
+	}
+	var err error
+	_, _ = in, mod
+
+%s
+	return err
+}
+`
+
+// Transpile compiles code and writes a self-contained Go source file to w
+// defining a function named funcName, in package pkg, that runs the
+// program directly against a []byte tape -- no Interpreter, no IR walked
+// at runtime. It reuses the same IR the optimizing Compile pass produces,
+// so collapsed "+"/"-"/"<"/">" runs, OpClear, OpScan, and OpMulMove all
+// become straight-line Go instead of a character-by-character loop, and
+// ordinary loops become "for tape[p] != 0 { ... }" built directly from the
+// matched OpJmpZ/OpJmpNZ pair rather than ops with goto-style jumps.
+//
+// The generated function has the signature
+//
+//	func <funcName>(r io.Reader, w io.Writer) error
+//
+// "!! clear" is translated to resetting the tape; the other "!!"
+// instructions are debugging aids with no static translation and are
+// skipped, the same way RunSpecialInstruction ignores instructions it
+// doesn't recognize.
+func Transpile(code string, pkg string, funcName string, w io.Writer) error {
+	prog, err := Compile(code)
+	if err != nil {
+		return err
+	}
+
+	var body strings.Builder
+	genBlock(prog.Ops, 0, len(prog.Ops), &body, "\t")
+
+	_, err = fmt.Fprintf(w, transpileTemplate, pkg, funcName, body.String())
+	return err
+}
+
+// genBlock writes Go source implementing ops[start:end] to out, indented
+// by indent.
+func genBlock(ops []Op, start, end int, out *strings.Builder, indent string) {
+	for i := start; i < end; {
+		op := ops[i]
+		switch op.Code {
+		case OpAdd:
+			fmt.Fprintf(out, "%stape[p] += byte(%d)\n", indent, byteConst(op.Operand))
+		case OpMove:
+			fmt.Fprintf(out, "%sp = mod(p + %d)\n", indent, op.Operand)
+		case OpOut:
+			genRepeated(out, indent, op.Operand, func(indent string) {
+				fmt.Fprintf(out, "%sif _, err = w.Write([]byte{tape[p]}); err != nil {\n", indent)
+				fmt.Fprintf(out, "%s\treturn err\n", indent)
+				fmt.Fprintf(out, "%s}\n", indent)
+			})
+		case OpIn:
+			genRepeated(out, indent, op.Operand, func(indent string) {
+				fmt.Fprintf(out, "%sif b, rerr := in.ReadByte(); rerr != nil {\n", indent)
+				fmt.Fprintf(out, "%s\ttape[p] = 0\n", indent)
+				fmt.Fprintf(out, "%s} else {\n", indent)
+				fmt.Fprintf(out, "%s\ttape[p] = b\n", indent)
+				fmt.Fprintf(out, "%s}\n", indent)
+			})
+		case OpClear:
+			fmt.Fprintf(out, "%stape[p] = 0\n", indent)
+		case OpScan:
+			fmt.Fprintf(out, "%sfor tape[p] != 0 {\n", indent)
+			fmt.Fprintf(out, "%s\tp = mod(p + %d)\n", indent, op.Operand)
+			fmt.Fprintf(out, "%s}\n", indent)
+		case OpMulMove:
+			fmt.Fprintf(out, "%sif c := tape[p]; c != 0 {\n", indent)
+			for _, target := range op.Targets {
+				fmt.Fprintf(out, "%s\ttape[mod(p+%d)] += c * byte(%d)\n", indent, target.Offset, byteConst(target.Mul))
+			}
+			fmt.Fprintf(out, "%s}\n", indent)
+			fmt.Fprintf(out, "%stape[p] = 0\n", indent)
+		case OpJmpZ:
+			fmt.Fprintf(out, "%sfor tape[p] != 0 {\n", indent)
+			genBlock(ops, i+1, op.Operand-1, out, indent+"\t")
+			fmt.Fprintf(out, "%s}\n", indent)
+			i = op.Operand
+			continue
+		case OpSpecial:
+			if len(op.Special) > 0 && op.Special[0] == "clear" {
+				fmt.Fprintf(out, "%stape = make([]byte, 30000)\n", indent)
+				fmt.Fprintf(out, "%sp = 0\n", indent)
+			}
+			// print, printn, and break are debugging aids with no static
+			// translation, and are skipped
+		}
+		i++
+	}
+}
+
+// byteConst reduces n to the 0-255 range a Go "byte(n)" conversion accepts
+// as a constant. Go rejects an untyped int constant that overflows byte
+// (e.g. "byte(-3)") at compile time, even though the runtime conversion
+// would wrap -- so op.Operand and MulTarget.Mul, which can be negative,
+// have to be folded into that range before being formatted as a literal.
+func byteConst(n int) int {
+	return ((n % 256) + 256) % 256
+}
+
+// genRepeated writes gen(indent) directly when n is 1, or wraps it in a
+// "for i := 0; i < n; i++" loop otherwise, avoiding an unrolled block for
+// a run of "." or "," that only repeats the same cell read/write.
+func genRepeated(out *strings.Builder, indent string, n int, gen func(indent string)) {
+	if n == 1 {
+		gen(indent)
+		return
+	}
+	fmt.Fprintf(out, "%sfor i := 0; i < %d; i++ {\n", indent, n)
+	gen(indent + "\t")
+	fmt.Fprintf(out, "%s}\n", indent)
+}
@@ -0,0 +1,51 @@
+package brainfuck
+
+import (
+	"bytes"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestTranspileProducesValidGo(t *testing.T) {
+	cases := []string{
+		"+++.",
+		"---.",
+		"+++[->+++>++<<].",
+		"+++[->-<].",
+		",[.,]",
+		"!! clear+.",
+	}
+
+	for _, code := range cases {
+		var buf bytes.Buffer
+		if err := Transpile(code, "gentest", "Run", &buf); err != nil {
+			t.Fatalf("%q: unexpected error: %s", code, err.Error())
+		}
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", buf.Bytes(), 0)
+		if err != nil {
+			t.Errorf("%q: generated code does not parse: %s\n%s", code, err.Error(), buf.String())
+			continue
+		}
+
+		// parsing alone accepts an overflowing constant conversion like
+		// byte(-3); type-checking is what actually catches it, the same
+		// way "go build" would.
+		conf := types.Config{Importer: importer.Default()}
+		if _, err := conf.Check("gentest", fset, []*ast.File{file}, nil); err != nil {
+			t.Errorf("%q: generated code does not type-check: %s\n%s", code, err.Error(), buf.String())
+		}
+	}
+}
+
+func TestTranspileRejectsInvalidSyntax(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Transpile("[", "main", "Run", &buf); err == nil {
+		t.Error("expected an error for unbalanced brackets, got none")
+	}
+}